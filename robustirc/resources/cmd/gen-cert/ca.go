@@ -0,0 +1,142 @@
+package main
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"flag"
+	"fmt"
+	"log"
+	"path/filepath"
+	"time"
+)
+
+// runCA implements the "ca" subcommand: it generates a long-lived,
+// self-signed certificate authority, suitable for distributing to clients
+// as a trust root.
+func runCA(args []string) {
+	fs := flag.NewFlagSet("ca", flag.ExitOnError)
+	host := fs.String("host", "localhost,n1,n2,n3,n4,n5", "Comma-separated hostnames and IP addresses to generate a certificate for")
+	outDir := fs.String("out-dir", "/tmp/", "Directory to write ca.pem and ca-key.pem into")
+	duration := fs.Duration("duration", 10*365*24*time.Hour, "Duration that the CA certificate is valid for")
+	startDate := fs.String("start-date", "", "Creation date formatted as Jan 1 15:04:05 2011; defaults to now")
+	organization := fs.String("organization", "RobustIRC localnet", "Organization to place in the certificate's subject")
+	commonName := fs.String("common-name", "", "Common name to place in the certificate's subject; defaults to the first --host entry")
+	keyType := fs.String("key-type", "rsa", "Private key type to generate: rsa, ecdsa-p256, ecdsa-p384, or ed25519")
+	rsaBits := fs.Int("rsa-bits", 2048, "Size of RSA key to generate; ignored for non-RSA --key-type")
+	ifExpiringWithin := fs.Duration("if-expiring-within", 0, "Only (re)generate if the existing certificate's SANs have changed or it expires within this duration; 0 always regenerates")
+	reuseKey := fs.Bool("reuse-key", false, "Reuse the existing private key instead of generating a new one when regenerating")
+	fs.Parse(args)
+
+	if len(*host) == 0 {
+		log.Fatal("missing required --host parameter")
+	}
+
+	ips, dnsNames := splitHosts(*host)
+	certPath := filepath.Join(*outDir, "ca.pem")
+	keyPath := filepath.Join(*outDir, "ca-key.pem")
+	if *ifExpiringWithin > 0 && !needsRegeneration(certPath, dnsNames, ips, *ifExpiringWithin) {
+		log.Printf("%s is still valid and its SANs are unchanged, not regenerating\n", certPath)
+		return
+	}
+
+	var (
+		priv any
+		err  error
+	)
+	if *reuseKey && fileExists(keyPath) {
+		priv, err = loadReusableKey(keyPath)
+		if err != nil {
+			log.Fatalf("failed to load existing private key for reuse: %s", err)
+		}
+	} else {
+		priv, err = generateKey(*keyType, *rsaBits)
+		if err != nil {
+			log.Fatalf("failed to generate private key: %s", err)
+		}
+	}
+
+	notBefore := time.Now()
+	if *startDate != "" {
+		notBefore, err = time.Parse("Jan 2 15:04:05 2006", *startDate)
+		if err != nil {
+			log.Fatalf("failed to parse --start-date: %s", err)
+		}
+	}
+	notAfter := notBefore.Add(*duration)
+
+	serialNumber, err := newSerialNumber()
+	if err != nil {
+		log.Fatalf("failed to generate serial number: %s", err)
+	}
+
+	cn := *commonName
+	if cn == "" {
+		if len(dnsNames) > 0 {
+			cn = dnsNames[0]
+		} else if len(ips) > 0 {
+			cn = ips[0].String()
+		}
+	}
+
+	template := x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			Organization: []string{*organization},
+			CommonName:   cn,
+		},
+		DNSNames:    dnsNames,
+		IPAddresses: ips,
+		NotBefore:   notBefore,
+		NotAfter:    notAfter,
+
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		IsCA:                  true,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+
+	derBytes, err := x509.CreateCertificate(rand.Reader, &template, &template, publicKey(priv), priv)
+	if err != nil {
+		log.Fatalf("failed to create certificate: %s", err)
+	}
+
+	if err := writeCertAndKey(*outDir, "ca.pem", "ca-key.pem", derBytes, priv); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// LoadCA reads a CA certificate and private key previously written by the
+// "ca" subcommand so that they can be used to sign leaf certificates. It
+// fails if the certificate is not actually a certificate authority, so that
+// pointing it at a leaf certificate by mistake raises an error instead of
+// silently minting certificates with a non-CA issuer.
+func LoadCA(certPath, keyPath string) (*x509.Certificate, crypto.Signer, error) {
+	certDER, err := pemDataFromFile(certPath, "CERTIFICATE")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read CA certificate: %s", err)
+	}
+	cert, err := x509.ParseCertificate(certDER)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse CA certificate: %s", err)
+	}
+	if !cert.IsCA || cert.KeyUsage&x509.KeyUsageCertSign == 0 {
+		return nil, nil, fmt.Errorf("%s is not a CA certificate (IsCA=%v, KeyUsageCertSign=%v)", certPath, cert.IsCA, cert.KeyUsage&x509.KeyUsageCertSign != 0)
+	}
+
+	keyDER, err := pemDataFromFile(keyPath, "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read CA key: %s", err)
+	}
+	key, err := parsePrivateKey(keyDER)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse CA key: %s", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, nil, fmt.Errorf("CA key of type %T does not implement crypto.Signer", key)
+	}
+
+	return cert, signer, nil
+}
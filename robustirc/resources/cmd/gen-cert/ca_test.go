@@ -0,0 +1,102 @@
+package main
+
+import (
+	"crypto/x509"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestCAIssueRoundTrip exercises the full "ca" -> "issue" -> reload-as-CA
+// flow: it regresses a bug where "issue" writing its leaf to the same
+// filenames as "ca" clobbered the CA's own cert/key.
+func TestCAIssueRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+
+	runCA([]string{"--host", "n1,n2,n3", "--out-dir", dir})
+	runIssue([]string{"--ca-dir", dir, "--out-dir", dir, "--host", "n1"})
+
+	ca, _, err := LoadCA(filepath.Join(dir, "ca.pem"), filepath.Join(dir, "ca-key.pem"))
+	if err != nil {
+		t.Fatalf("LoadCA after issue: %s (the CA's files were clobbered by the leaf)", err)
+	}
+	if !ca.IsCA {
+		t.Fatal("ca.pem is no longer a CA certificate after issuing a leaf")
+	}
+
+	leafDER, err := pemDataFromFile(filepath.Join(dir, "cert.pem"), "CERTIFICATE")
+	if err != nil {
+		t.Fatalf("reading issued leaf cert.pem: %s", err)
+	}
+	leaf, err := x509.ParseCertificate(leafDER)
+	if err != nil {
+		t.Fatalf("parsing issued leaf cert.pem: %s", err)
+	}
+	if leaf.IsCA {
+		t.Fatal("issued leaf certificate has IsCA=true")
+	}
+	if err := leaf.CheckSignatureFrom(ca); err != nil {
+		t.Fatalf("leaf certificate does not validate against the CA: %s", err)
+	}
+}
+
+func TestIssueLeafValidatesAgainstCA(t *testing.T) {
+	dir := t.TempDir()
+	runCA([]string{"--host", "n1", "--out-dir", dir})
+
+	ca, caKey, err := LoadCA(filepath.Join(dir, "ca.pem"), filepath.Join(dir, "ca-key.pem"))
+	if err != nil {
+		t.Fatalf("LoadCA: %s", err)
+	}
+
+	notBefore := time.Now()
+	derBytes, _, err := IssueLeaf(ca, caKey, LeafSpec{
+		DNSNames:     []string{"n2"},
+		CommonName:   "n2",
+		Organization: "test",
+		NotBefore:    notBefore,
+		NotAfter:     notBefore.Add(24 * time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		KeyType:      "ecdsa-p256",
+	})
+	if err != nil {
+		t.Fatalf("IssueLeaf: %s", err)
+	}
+	leaf, err := x509.ParseCertificate(derBytes)
+	if err != nil {
+		t.Fatalf("ParseCertificate: %s", err)
+	}
+	if err := leaf.CheckSignatureFrom(ca); err != nil {
+		t.Fatalf("leaf certificate does not validate against the CA: %s", err)
+	}
+}
+
+// LoadCA must refuse to treat a non-CA certificate as a trust root.
+func TestLoadCARejectsNonCACert(t *testing.T) {
+	dir := t.TempDir()
+	runCA([]string{"--host", "n1", "--out-dir", dir})
+	ca, caKey, err := LoadCA(filepath.Join(dir, "ca.pem"), filepath.Join(dir, "ca-key.pem"))
+	if err != nil {
+		t.Fatalf("LoadCA: %s", err)
+	}
+
+	notBefore := time.Now()
+	derBytes, priv, err := IssueLeaf(ca, caKey, LeafSpec{
+		DNSNames:    []string{"n1"},
+		CommonName:  "n1",
+		NotBefore:   notBefore,
+		NotAfter:    notBefore.Add(24 * time.Hour),
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		KeyType:     "ecdsa-p256",
+	})
+	if err != nil {
+		t.Fatalf("IssueLeaf: %s", err)
+	}
+	if err := writeCertAndKey(dir, "leaf.pem", "leaf-key.pem", derBytes, priv); err != nil {
+		t.Fatalf("writeCertAndKey: %s", err)
+	}
+
+	if _, _, err := LoadCA(filepath.Join(dir, "leaf.pem"), filepath.Join(dir, "leaf-key.pem")); err == nil {
+		t.Fatal("LoadCA accepted a non-CA certificate, want an error")
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"crypto/x509"
+	"flag"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// runIssueClient implements the "issue-client" subcommand: it loads a CA
+// previously written by the "ca" subcommand and mints one client-auth leaf
+// certificate per name in --clients, for use as mTLS client identities
+// against databases (etcd, CockroachDB, FoundationDB, ...) that require
+// mutual TLS.
+func runIssueClient(args []string) {
+	fs := flag.NewFlagSet("issue-client", flag.ExitOnError)
+	caDir := fs.String("ca-dir", "/tmp/", "Directory containing the CA's ca.pem and ca-key.pem")
+	clients := fs.String("clients", "", "Comma-separated list of client identities to issue certificates for")
+	outDir := fs.String("out-dir", "/tmp/", "Directory to write client-<name>.pem and client-<name>-key.pem into")
+	duration := fs.Duration("duration", 90*24*time.Hour, "Duration that each client certificate is valid for")
+	startDate := fs.String("start-date", "", "Creation date formatted as Jan 1 15:04:05 2011; defaults to now")
+	organization := fs.String("organization", "RobustIRC localnet", "Organization to place in each certificate's subject")
+	keyType := fs.String("key-type", "rsa", "Private key type to generate: rsa, ecdsa-p256, ecdsa-p384, or ed25519")
+	rsaBits := fs.Int("rsa-bits", 2048, "Size of RSA key to generate; ignored for non-RSA --key-type")
+	ifExpiringWithin := fs.Duration("if-expiring-within", 0, "Only (re)issue a client certificate if its SANs have changed or it expires within this duration; 0 always reissues")
+	reuseKey := fs.Bool("reuse-key", false, "Reuse each client's existing private key instead of generating a new one when reissuing")
+	fs.Parse(args)
+
+	if len(*clients) == 0 {
+		log.Fatal("missing required --clients parameter")
+	}
+
+	ca, caKey, err := LoadCA(filepath.Join(*caDir, "ca.pem"), filepath.Join(*caDir, "ca-key.pem"))
+	if err != nil {
+		log.Fatalf("failed to load CA: %s", err)
+	}
+
+	notBefore := time.Now()
+	if *startDate != "" {
+		notBefore, err = time.Parse("Jan 2 15:04:05 2006", *startDate)
+		if err != nil {
+			log.Fatalf("failed to parse --start-date: %s", err)
+		}
+	}
+	notAfter := notBefore.Add(*duration)
+
+	for _, name := range strings.Split(*clients, ",") {
+		certName := "client-" + name + ".pem"
+		keyName := "client-" + name + "-key.pem"
+		certPath := filepath.Join(*outDir, certName)
+		keyPath := filepath.Join(*outDir, keyName)
+
+		if *ifExpiringWithin > 0 && !needsRegeneration(certPath, nil, nil, *ifExpiringWithin) {
+			log.Printf("%s is still valid, not reissuing\n", certPath)
+			continue
+		}
+
+		var reusedKey any
+		if *reuseKey && fileExists(keyPath) {
+			reusedKey, err = loadReusableKey(keyPath)
+			if err != nil {
+				log.Fatalf("failed to load existing private key for %q for reuse: %s", name, err)
+			}
+		}
+
+		derBytes, priv, err := IssueLeaf(ca, caKey, LeafSpec{
+			CommonName:   name,
+			Organization: *organization,
+			NotBefore:    notBefore,
+			NotAfter:     notAfter,
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+			KeyType:      *keyType,
+			RSABits:      *rsaBits,
+			Key:          reusedKey,
+		})
+		if err != nil {
+			log.Fatalf("failed to issue client certificate for %q: %s", name, err)
+		}
+
+		if err := writeCertAndKey(*outDir, certName, keyName, derBytes, priv); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"crypto/x509"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestIssueClientRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	runCA([]string{"--host", "n1", "--out-dir", dir})
+	runIssueClient([]string{"--ca-dir", dir, "--out-dir", dir, "--clients", "app,migrator"})
+
+	ca, _, err := LoadCA(filepath.Join(dir, "ca.pem"), filepath.Join(dir, "ca-key.pem"))
+	if err != nil {
+		t.Fatalf("LoadCA: %s", err)
+	}
+
+	for _, name := range []string{"app", "migrator"} {
+		certPath := filepath.Join(dir, "client-"+name+".pem")
+		der, err := pemDataFromFile(certPath, "CERTIFICATE")
+		if err != nil {
+			t.Fatalf("reading %s: %s", certPath, err)
+		}
+		keyPath := filepath.Join(dir, "client-"+name+"-key.pem")
+		if _, err := pemDataFromFile(keyPath, ""); err != nil {
+			t.Fatalf("reading %s: %s", keyPath, err)
+		}
+
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			t.Fatalf("parsing %s: %s", certPath, err)
+		}
+		if cert.IsCA {
+			t.Errorf("client certificate for %q has IsCA=true", name)
+		}
+		if len(cert.ExtKeyUsage) != 1 || cert.ExtKeyUsage[0] != x509.ExtKeyUsageClientAuth {
+			t.Errorf("client certificate for %q has ExtKeyUsage=%v, want [ClientAuth]", name, cert.ExtKeyUsage)
+		}
+		if err := cert.CheckSignatureFrom(ca); err != nil {
+			t.Errorf("client certificate for %q does not validate against the CA: %s", name, err)
+		}
+	}
+}
+
+func TestIssueClientIfExpiringWithinSkipsUnexpiredCert(t *testing.T) {
+	dir := t.TempDir()
+	runCA([]string{"--host", "n1", "--out-dir", dir})
+	runIssueClient([]string{"--ca-dir", dir, "--out-dir", dir, "--clients", "app", "--duration", "720h"})
+
+	certPath := filepath.Join(dir, "client-app.pem")
+	before, err := pemDataFromFile(certPath, "CERTIFICATE")
+	if err != nil {
+		t.Fatalf("reading %s: %s", certPath, err)
+	}
+
+	runIssueClient([]string{"--ca-dir", dir, "--out-dir", dir, "--clients", "app", "--duration", "720h", "--if-expiring-within", "1h"})
+
+	after, err := pemDataFromFile(certPath, "CERTIFICATE")
+	if err != nil {
+		t.Fatalf("reading %s: %s", certPath, err)
+	}
+	if string(before) != string(after) {
+		t.Error("issue-client reissued a certificate that was not expiring soon and had unchanged SANs")
+	}
+}
+
+func TestIssueClientReuseKeyKeepsKeyAcrossReissue(t *testing.T) {
+	dir := t.TempDir()
+	runCA([]string{"--host", "n1", "--out-dir", dir})
+	runIssueClient([]string{"--ca-dir", dir, "--out-dir", dir, "--clients", "app", "--duration", "1h"})
+
+	keyPath := filepath.Join(dir, "client-app-key.pem")
+	beforeKey, err := pemDataFromFile(keyPath, "")
+	if err != nil {
+		t.Fatalf("reading %s: %s", keyPath, err)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	runIssueClient([]string{"--ca-dir", dir, "--out-dir", dir, "--clients", "app", "--duration", "1h", "--if-expiring-within", "24h", "--reuse-key"})
+
+	afterKey, err := pemDataFromFile(keyPath, "")
+	if err != nil {
+		t.Fatalf("reading %s: %s", keyPath, err)
+	}
+	if string(beforeKey) != string(afterKey) {
+		t.Error("issue-client with --reuse-key generated a new private key instead of reusing the existing one")
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"flag"
+	"log"
+	"net"
+	"path/filepath"
+	"time"
+)
+
+// LeafSpec describes a leaf certificate to be issued by IssueLeaf.
+type LeafSpec struct {
+	DNSNames     []string
+	IPAddresses  []net.IP
+	CommonName   string
+	Organization string
+	NotBefore    time.Time
+	NotAfter     time.Time
+	ExtKeyUsage  []x509.ExtKeyUsage
+	KeyType      string
+	RSABits      int
+
+	// Key, if non-nil, is reused as the leaf's private key instead of
+	// generating a fresh one (see --reuse-key).
+	Key any
+}
+
+// IssueLeaf mints a short-lived leaf certificate signed by ca/caKey. Unlike
+// the CA certificate itself, the result is not a certificate authority: it
+// carries only the key usages needed to serve (or, for client certs,
+// authenticate) TLS connections.
+func IssueLeaf(ca *x509.Certificate, caKey crypto.Signer, spec LeafSpec) (derBytes []byte, priv any, err error) {
+	if spec.Key != nil {
+		priv = spec.Key
+	} else {
+		priv, err = generateKey(spec.KeyType, spec.RSABits)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	serialNumber, err := newSerialNumber()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	template := x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			Organization: []string{spec.Organization},
+			CommonName:   spec.CommonName,
+		},
+		DNSNames:    spec.DNSNames,
+		IPAddresses: spec.IPAddresses,
+		NotBefore:   spec.NotBefore,
+		NotAfter:    spec.NotAfter,
+
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		IsCA:                  false,
+		ExtKeyUsage:           spec.ExtKeyUsage,
+		BasicConstraintsValid: true,
+	}
+
+	derBytes, err = x509.CreateCertificate(rand.Reader, &template, ca, publicKey(priv), caKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return derBytes, priv, nil
+}
+
+// runIssue implements the "issue" subcommand: it loads a CA previously
+// written by the "ca" subcommand and mints a short-lived leaf certificate
+// for --host, usable for both server and client authentication.
+func runIssue(args []string) {
+	fs := flag.NewFlagSet("issue", flag.ExitOnError)
+	caDir := fs.String("ca-dir", "/tmp/", "Directory containing the CA's ca.pem and ca-key.pem")
+	host := fs.String("host", "", "Comma-separated hostnames and IP addresses to issue a leaf certificate for")
+	outDir := fs.String("out-dir", "/tmp/", "Directory to write cert.pem and key.pem into")
+	duration := fs.Duration("duration", 90*24*time.Hour, "Duration that the leaf certificate is valid for")
+	startDate := fs.String("start-date", "", "Creation date formatted as Jan 1 15:04:05 2011; defaults to now")
+	organization := fs.String("organization", "RobustIRC localnet", "Organization to place in the certificate's subject")
+	commonName := fs.String("common-name", "", "Common name to place in the certificate's subject; defaults to the first --host entry")
+	keyType := fs.String("key-type", "rsa", "Private key type to generate: rsa, ecdsa-p256, ecdsa-p384, or ed25519")
+	rsaBits := fs.Int("rsa-bits", 2048, "Size of RSA key to generate; ignored for non-RSA --key-type")
+	ifExpiringWithin := fs.Duration("if-expiring-within", 0, "Only (re)issue if the existing certificate's SANs have changed or it expires within this duration; 0 always reissues")
+	reuseKey := fs.Bool("reuse-key", false, "Reuse the existing private key instead of generating a new one when reissuing")
+	fs.Parse(args)
+
+	if len(*host) == 0 {
+		log.Fatal("missing required --host parameter")
+	}
+
+	ips, dnsNames := splitHosts(*host)
+	certPath := filepath.Join(*outDir, "cert.pem")
+	keyPath := filepath.Join(*outDir, "key.pem")
+	if *ifExpiringWithin > 0 && !needsRegeneration(certPath, dnsNames, ips, *ifExpiringWithin) {
+		log.Printf("%s is still valid and its SANs are unchanged, not reissuing\n", certPath)
+		return
+	}
+
+	ca, caKey, err := LoadCA(filepath.Join(*caDir, "ca.pem"), filepath.Join(*caDir, "ca-key.pem"))
+	if err != nil {
+		log.Fatalf("failed to load CA: %s", err)
+	}
+
+	var reusedKey any
+	if *reuseKey && fileExists(keyPath) {
+		reusedKey, err = loadReusableKey(keyPath)
+		if err != nil {
+			log.Fatalf("failed to load existing private key for reuse: %s", err)
+		}
+	}
+
+	notBefore := time.Now()
+	if *startDate != "" {
+		notBefore, err = time.Parse("Jan 2 15:04:05 2006", *startDate)
+		if err != nil {
+			log.Fatalf("failed to parse --start-date: %s", err)
+		}
+	}
+
+	cn := *commonName
+	if cn == "" {
+		if len(dnsNames) > 0 {
+			cn = dnsNames[0]
+		} else if len(ips) > 0 {
+			cn = ips[0].String()
+		}
+	}
+
+	derBytes, priv, err := IssueLeaf(ca, caKey, LeafSpec{
+		DNSNames:     dnsNames,
+		IPAddresses:  ips,
+		CommonName:   cn,
+		Organization: *organization,
+		NotBefore:    notBefore,
+		NotAfter:     notBefore.Add(*duration),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		KeyType:      *keyType,
+		RSABits:      *rsaBits,
+		Key:          reusedKey,
+	})
+	if err != nil {
+		log.Fatalf("failed to issue leaf certificate: %s", err)
+	}
+
+	if err := writeCertAndKey(*outDir, "cert.pem", "key.pem", derBytes, priv); err != nil {
+		log.Fatal(err)
+	}
+}
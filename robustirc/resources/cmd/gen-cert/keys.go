@@ -0,0 +1,128 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// generateKey creates a new private key of the given type ("rsa",
+// "ecdsa-p256", "ecdsa-p384", or "ed25519"). rsaBits is only consulted for
+// "rsa".
+func generateKey(keyType string, rsaBits int) (any, error) {
+	switch keyType {
+	case "rsa":
+		return rsa.GenerateKey(rand.Reader, rsaBits)
+	case "ecdsa-p256":
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "ecdsa-p384":
+		return ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case "ed25519":
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		return priv, err
+	default:
+		return nil, fmt.Errorf("unknown key type %q (want rsa, ecdsa-p256, ecdsa-p384, or ed25519)", keyType)
+	}
+}
+
+func publicKey(priv any) any {
+	switch k := priv.(type) {
+	case *rsa.PrivateKey:
+		return &k.PublicKey
+	case *ecdsa.PrivateKey:
+		return &k.PublicKey
+	case ed25519.PrivateKey:
+		return k.Public()
+	default:
+		return nil
+	}
+}
+
+// splitHosts partitions a comma-separated --host value into IP SANs and DNS
+// SANs, the same way crypto/tls/generate_cert.go does.
+func splitHosts(hosts string) (ips []net.IP, dnsNames []string) {
+	for _, h := range strings.Split(hosts, ",") {
+		if ip := net.ParseIP(h); ip != nil {
+			ips = append(ips, ip)
+		} else {
+			dnsNames = append(dnsNames, h)
+		}
+	}
+	return ips, dnsNames
+}
+
+// pemDataFromFile reads a single PEM block from path and returns its
+// decoded bytes. If blockType is non-empty, the block's Type must match it.
+func pemDataFromFile(path, blockType string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s contains no PEM data", path)
+	}
+	if blockType != "" && block.Type != blockType {
+		return nil, fmt.Errorf("%s contains a %s block, expected %s", path, block.Type, blockType)
+	}
+	return block.Bytes, nil
+}
+
+// parsePrivateKey parses a DER-encoded private key in any of the formats
+// pemBlockForKey can produce (PKCS#1 RSA or PKCS#8).
+func parsePrivateKey(der []byte) (any, error) {
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+	return x509.ParsePKCS8PrivateKey(der)
+}
+
+func newSerialNumber() (*big.Int, error) {
+	serialNumberLimit := new(big.Int).Lsh(big.NewInt(1), 128)
+	return rand.Int(rand.Reader, serialNumberLimit)
+}
+
+func pemBlockForKey(priv any) *pem.Block {
+	switch k := priv.(type) {
+	case *rsa.PrivateKey:
+		return &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(k)}
+	default:
+		b, err := x509.MarshalPKCS8PrivateKey(priv)
+		if err != nil {
+			log.Fatalf("failed to marshal private key: %s", err)
+		}
+		return &pem.Block{Type: "PRIVATE KEY", Bytes: b}
+	}
+}
+
+// writeCertAndKey PEM-encodes derBytes and priv as certName and keyName
+// inside dir.
+func writeCertAndKey(dir, certName, keyName string, derBytes []byte, priv any) error {
+	certOut, err := os.Create(filepath.Join(dir, certName))
+	if err != nil {
+		return fmt.Errorf("failed to open %s for writing: %s", certName, err)
+	}
+	pem.Encode(certOut, &pem.Block{Type: "CERTIFICATE", Bytes: derBytes})
+	certOut.Close()
+	log.Printf("written %s\n", certName)
+
+	keyOut, err := os.OpenFile(filepath.Join(dir, keyName), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open %s for writing: %s", keyName, err)
+	}
+	pem.Encode(keyOut, pemBlockForKey(priv))
+	keyOut.Close()
+	log.Printf("written %s\n", keyName)
+	return nil
+}
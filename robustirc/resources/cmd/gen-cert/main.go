@@ -0,0 +1,36 @@
+// Command gen-cert generates TLS certificates for use in Jepsen test
+// harnesses (originally written for RobustIRC's local test cluster, where
+// every node needs to speak TLS to every other node). It is modeled on
+// Go's crypto/tls/generate_cert.go, split into a "ca" subcommand that
+// produces a long-lived trust root, an "issue" subcommand that mints
+// short-lived, rotatable leaf certificates signed by that root, and an
+// "issue-client" subcommand that mints client-auth certificates for mTLS.
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: %s (ca|issue|issue-client) [flags]\n", os.Args[0])
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	switch os.Args[1] {
+	case "ca":
+		runCA(os.Args[2:])
+	case "issue":
+		runIssue(os.Args[2:])
+	case "issue-client":
+		runIssueClient(os.Args[2:])
+	default:
+		log.Printf("unknown subcommand %q", os.Args[1])
+		usage()
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"crypto/x509"
+	"net"
+	"os"
+	"sort"
+	"time"
+)
+
+// needsRegeneration inspects the certificate at certPath (if any) and
+// reports whether it should be (re)generated: the file is missing or
+// unreadable, its requested SANs no longer match dnsNames/ips, or its
+// remaining lifetime (NotAfter - now) is less than within.
+func needsRegeneration(certPath string, dnsNames []string, ips []net.IP, within time.Duration) bool {
+	certDER, err := pemDataFromFile(certPath, "CERTIFICATE")
+	if err != nil {
+		return true
+	}
+	cert, err := x509.ParseCertificate(certDER)
+	if err != nil {
+		return true
+	}
+	if !sameSANs(cert.DNSNames, dnsNames) || !sameIPs(cert.IPAddresses, ips) {
+		return true
+	}
+	return time.Until(cert.NotAfter) < within
+}
+
+func sameSANs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a, b = append([]string(nil), a...), append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func sameIPs(a, b []net.IP) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	as := make([]string, len(a))
+	bs := make([]string, len(b))
+	for i, ip := range a {
+		as[i] = ip.String()
+	}
+	for i, ip := range b {
+		bs[i] = ip.String()
+	}
+	sort.Strings(as)
+	sort.Strings(bs)
+	for i := range as {
+		if as[i] != bs[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// loadReusableKey parses the private key at keyPath so it can be reused
+// across a cert regeneration instead of minting a fresh one.
+func loadReusableKey(keyPath string) (any, error) {
+	der, err := pemDataFromFile(keyPath, "")
+	if err != nil {
+		return nil, err
+	}
+	return parsePrivateKey(der)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSameSANs(t *testing.T) {
+	cases := []struct {
+		a, b []string
+		want bool
+	}{
+		{nil, nil, true},
+		{[]string{"n1", "n2"}, []string{"n2", "n1"}, true},
+		{[]string{"n1"}, []string{"n1", "n2"}, false},
+		{[]string{"n1"}, []string{"n2"}, false},
+	}
+	for _, c := range cases {
+		if got := sameSANs(c.a, c.b); got != c.want {
+			t.Errorf("sameSANs(%v, %v) = %v, want %v", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestSameIPs(t *testing.T) {
+	cases := []struct {
+		a, b []net.IP
+		want bool
+	}{
+		{nil, nil, true},
+		{[]net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.2")}, []net.IP{net.ParseIP("10.0.0.2"), net.ParseIP("10.0.0.1")}, true},
+		{[]net.IP{net.ParseIP("10.0.0.1")}, []net.IP{net.ParseIP("10.0.0.2")}, false},
+		{[]net.IP{net.ParseIP("10.0.0.1")}, nil, false},
+	}
+	for _, c := range cases {
+		if got := sameIPs(c.a, c.b); got != c.want {
+			t.Errorf("sameIPs(%v, %v) = %v, want %v", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+// writeTestCert writes a minimal self-signed certificate valid from
+// notBefore to notAfter with the given DNS SANs to dir/cert.pem.
+func writeTestCert(t *testing.T, dir string, dnsNames []string, notBefore, notAfter time.Time) string {
+	t.Helper()
+	priv, err := generateKey("rsa", 2048)
+	if err != nil {
+		t.Fatalf("generateKey: %s", err)
+	}
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		DNSNames:     dnsNames,
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	derBytes, err := x509.CreateCertificate(rand.Reader, &template, &template, publicKey(priv), priv)
+	if err != nil {
+		t.Fatalf("CreateCertificate: %s", err)
+	}
+	certPath := filepath.Join(dir, "cert.pem")
+	if err := writeCertAndKey(dir, "cert.pem", "key.pem", derBytes, priv); err != nil {
+		t.Fatalf("writeCertAndKey: %s", err)
+	}
+	return certPath
+}
+
+func TestNeedsRegenerationMissingFile(t *testing.T) {
+	dir := t.TempDir()
+	if !needsRegeneration(filepath.Join(dir, "cert.pem"), []string{"n1"}, nil, time.Hour) {
+		t.Error("needsRegeneration() = false for a missing certificate, want true")
+	}
+}
+
+func TestNeedsRegenerationStillValid(t *testing.T) {
+	dir := t.TempDir()
+	certPath := writeTestCert(t, dir, []string{"n1", "n2"}, time.Now(), time.Now().Add(30*24*time.Hour))
+	if needsRegeneration(certPath, []string{"n1", "n2"}, nil, 24*time.Hour) {
+		t.Error("needsRegeneration() = true for a cert with matching SANs and ample lifetime, want false")
+	}
+}
+
+func TestNeedsRegenerationExpiringSoon(t *testing.T) {
+	dir := t.TempDir()
+	certPath := writeTestCert(t, dir, []string{"n1"}, time.Now(), time.Now().Add(time.Hour))
+	if !needsRegeneration(certPath, []string{"n1"}, nil, 24*time.Hour) {
+		t.Error("needsRegeneration() = false for a cert expiring within the window, want true")
+	}
+}
+
+func TestNeedsRegenerationSANsChanged(t *testing.T) {
+	dir := t.TempDir()
+	certPath := writeTestCert(t, dir, []string{"n1"}, time.Now(), time.Now().Add(30*24*time.Hour))
+	if !needsRegeneration(certPath, []string{"n1", "n2"}, nil, 24*time.Hour) {
+		t.Error("needsRegeneration() = false when the requested SANs changed, want true")
+	}
+}